@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ianremmler/crosswd"
+	"github.com/ianremmler/crosswd/ui"
+)
+
+// clueEntry is one row of the clue-list pane.
+type clueEntry struct {
+	id   int
+	dir  crosswd.Direction
+	text string
+}
+
+// clueEntries returns every clue in dir, in grid order, formatted as
+// "<num><A|D> <clue text>".
+func clueEntries(dir crosswd.Direction) []clueEntry {
+	dirc := 'A'
+	if dir == crosswd.Down {
+		dirc = 'D'
+	}
+	var entries []clueEntry
+	for _, id := range cw.Words(dir) {
+		pos, _ := cw.WordStart(id)
+		entries = append(entries, clueEntry{
+			id:   id,
+			dir:  dir,
+			text: fmt.Sprintf("%d%c %s", id, dirc, cw.Clue(pos, dir)),
+		})
+	}
+	return entries
+}
+
+// drawCluePane renders the Across and Down clue list starting at column x,
+// scrolled to keep the active word visible, highlighting it with sty.
+func drawCluePane(x, height int, sty style) {
+	entries := append(clueEntries(crosswd.Right), clueEntries(crosswd.Down)...)
+	activeID := cw.WordID(loc, dir)
+	active := -1
+	for i, e := range entries {
+		if e.id == activeID && e.dir == dir {
+			active = i
+			break
+		}
+	}
+	rows := height - 1
+	if rows < 1 {
+		rows = 1
+	}
+	if active >= 0 {
+		if active < clueScroll {
+			clueScroll = active
+		} else if active >= clueScroll+rows {
+			clueScroll = active - rows + 1
+		}
+	}
+	for i := 0; i < rows && clueScroll+i < len(entries); i++ {
+		e := entries[clueScroll+i]
+		lineStyle := style{ui.ColorDefault, ui.ColorDefault}
+		if e.id == activeID && e.dir == dir {
+			lineStyle = sty
+		}
+		for dx, r := range e.text {
+			rend.SetCell(x+dx, 1+i, r, lineStyle.fg, lineStyle.bg)
+		}
+	}
+}
+
+// clueMatch is a scored fuzzy-search hit against the clue list.
+type clueMatch struct {
+	id    int
+	dir   crosswd.Direction
+	score int
+}
+
+// bestClueMatch returns the highest-scoring clue matching query (ranked by
+// descending score, then ascending clue number), or nil if query is empty
+// or matches nothing.
+func bestClueMatch(query string) *clueMatch {
+	if query == "" {
+		return nil
+	}
+	var best *clueMatch
+	for _, dir := range []crosswd.Direction{crosswd.Right, crosswd.Down} {
+		for _, e := range clueEntries(dir) {
+			score, ok := fuzzyScore(e.text, query)
+			if !ok {
+				continue
+			}
+			if best == nil || score > best.score || (score == best.score && e.id < best.id) {
+				best = &clueMatch{id: e.id, dir: e.dir, score: score}
+			}
+		}
+	}
+	return best
+}
+
+// fuzzyScore reports whether query is a subsequence of text and, if so, its
+// match score: each matched character scores 1, a character starting a word
+// (the first character, or one following a non-alphanumeric rune) scores an
+// additional 2, and a character matched immediately after the previous
+// match scores an additional 1.
+func fuzzyScore(text, query string) (int, bool) {
+	t := []rune(strings.ToLower(text))
+	q := []rune(strings.ToLower(query))
+	score := 0
+	qi := 0
+	chained := false
+	for i, r := range t {
+		if qi >= len(q) {
+			break
+		}
+		if r != q[qi] {
+			chained = false
+			continue
+		}
+		score++
+		if i == 0 || !isWordChar(t[i-1]) {
+			score += 2
+		}
+		if chained {
+			score++
+		}
+		chained = true
+		qi++
+	}
+	return score, qi == len(q)
+}
+
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -9,7 +10,7 @@ import (
 	"unicode"
 
 	"github.com/ianremmler/crosswd"
-	"github.com/nsf/termbox-go"
+	"github.com/ianremmler/crosswd/ui"
 )
 
 type runMode int
@@ -17,32 +18,75 @@ type runMode int
 const (
 	normalMode runMode = iota
 	editMode
+	rebusMode
+	clueMode
 	quitMode
 )
 
+// cluePaneGap is how far the clue-list pane sits to the right of the
+// puzzle info column.
+const cluePaneGap = 30
+
 type style struct {
-	fg, bg termbox.Attribute
+	fg, bg ui.Attr
+}
+
+// cellWidth is the number of terminal columns used per puzzle cell: enough
+// to fit the puzzle's largest clue number, plus one for the cell's letter.
+// setCellWidth sets it once the puzzle is loaded.
+var cellWidth = 3
+
+// setCellWidth sizes cellWidth to fit cw's largest clue number, so the
+// overlay never truncates a clue number into a different, shorter one.
+func setCellWidth() {
+	maxNum := 0
+	sz := cw.Size()
+	for y := 0; y < sz.Y; y++ {
+		for x := 0; x < sz.X; x++ {
+			if n := cw.Number(crosswd.Coord{x, y}); n > maxNum {
+				maxNum = n
+			}
+		}
+	}
+	cellWidth = len(strconv.Itoa(maxNum)) + 1
+	if cellWidth < 3 {
+		cellWidth = 3
+	}
 }
 
+// numCol and letterCol return the screen columns of the clue number and
+// letter for puzzle column x.
+func numCol(x int) int    { return 1 + cellWidth*x }
+func letterCol(x int) int { return numCol(x) + cellWidth - 1 }
+
 var (
 	cw          *crosswd.Puzzle
+	rend        ui.Renderer
 	mode        = normalMode
 	loc         crosswd.Coord
 	dir         = crosswd.Right
 	modified    = false
 	count       = 0
 	cheat       = false
+	rebusBuf    string
+	clueBuf     string
+	clueScroll  = 0
 	notice      string
 	filename    string
-	normalStyle = style{termbox.ColorBlack, termbox.ColorWhite}
-	selectStyle = style{termbox.ColorWhite, termbox.ColorBlue}
-	editStyle   = style{termbox.ColorWhite, termbox.ColorRed}
-	solvedStyle = style{termbox.ColorBlack, termbox.ColorGreen}
-	cheatColor  = termbox.ColorYellow
+	normalStyle = style{ui.ColorBlack, ui.ColorWhite}
+	selectStyle = style{ui.ColorWhite, ui.ColorBlue}
+	editStyle   = style{ui.ColorWhite, ui.ColorRed}
+	solvedStyle = style{ui.ColorBlack, ui.ColorGreen}
+	cheatColor  = ui.ColorYellow
 )
 
 func draw() {
-	termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
+	width, height := rend.Size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			rend.SetCell(x, y, ' ', ui.ColorDefault, ui.ColorDefault)
+		}
+	}
 	wordStyle := selectStyle
 	if mode == editMode {
 		wordStyle = editStyle
@@ -72,6 +116,10 @@ func draw() {
 					}
 				}
 			}
+			if rebus, ok := cw.RebusAt(crosswd.Coord{x, y}); ok {
+				c = rebus[0]
+				sty.fg |= ui.AttrUnderline
+			}
 			switch c {
 			case crosswd.Empty:
 				c = '_'
@@ -79,14 +127,34 @@ func draw() {
 				c = ' '
 				sty.fg, sty.bg = sty.bg, sty.fg
 			}
-			termbox.SetCell(x+1, y+1, rune(c), sty.fg, sty.bg)
+			numWidth := cellWidth - 1
+			for i := 0; i < numWidth; i++ {
+				rend.SetCell(numCol(x)+i, y+1, ' ', sty.fg, sty.bg)
+			}
+			if n := cw.Number(crosswd.Coord{x, y}); n > 0 {
+				s := strconv.Itoa(n)
+				for i, r := range s {
+					rend.SetCell(numCol(x)+numWidth-len(s)+i, y+1, r, sty.fg, sty.bg)
+				}
+			}
+			rend.SetCell(letterCol(x), y+1, rune(c), sty.fg, sty.bg)
+		}
+	}
+	if mode == rebusMode {
+		for x, r := range rebusBuf {
+			rend.SetCell(letterCol(loc.X)+x, loc.Y+1, r, editStyle.fg, editStyle.bg)
+		}
+		if rebusBuf == "" {
+			rend.SetCell(letterCol(loc.X), loc.Y+1, '_', editStyle.fg, editStyle.bg)
 		}
 	}
 
+	infoX := cellWidth*sz.X + 3
+
 	// puzzle info
 	for y, s := range []string{cw.Title, cw.Author, cw.Copyright} {
 		for x, r := range s {
-			termbox.SetCell(sz.X+3+x, y+1, r, termbox.ColorDefault, termbox.ColorDefault)
+			rend.SetCell(infoX+x, y+1, r, ui.ColorDefault, ui.ColorDefault)
 		}
 	}
 
@@ -101,10 +169,15 @@ func draw() {
 	if count != 0 {
 		status = append(status, strconv.Itoa(count))
 	}
+	if mode == clueMode {
+		status = append(status, "/"+clueBuf)
+	}
 	for x, r := range strings.Join(status, " ") {
-		termbox.SetCell(sz.X+3+x, sz.Y, r, termbox.ColorDefault, termbox.ColorDefault)
+		rend.SetCell(infoX+x, sz.Y, r, ui.ColorDefault, ui.ColorDefault)
 	}
 
+	drawCluePane(infoX+cluePaneGap, height, wordStyle)
+
 	// clue
 	id := cw.WordID(loc, dir)
 	clue := cw.Clue(loc, dir)
@@ -117,11 +190,10 @@ func draw() {
 		wordLen = end.Y - start.Y + 1
 	}
 	for x, r := range fmt.Sprintf("%d%c(%d): %s", id, dirc, wordLen, clue) {
-		termbox.SetCell(x+1, sz.Y+2, r, termbox.ColorDefault, termbox.ColorDefault)
+		rend.SetCell(x+1, sz.Y+2, r, ui.ColorDefault, ui.ColorDefault)
 	}
 
 	// notes
-	width, _ := termbox.Size()
 	var notes []string
 	for _, para := range strings.Split(cw.Notes, "\n") {
 		notes = append(notes, wrapText(strings.TrimSpace(para), width-2))
@@ -133,32 +205,48 @@ func draw() {
 			x = 0
 			y++
 		} else {
-			termbox.SetCell(x+1, sz.Y+4+y, r, termbox.ColorDefault, termbox.ColorDefault)
+			rend.SetCell(x+1, sz.Y+4+y, r, ui.ColorDefault, ui.ColorDefault)
 			x++
 		}
 	}
 
-	termbox.SetCursor(loc.X+1, loc.Y+1)
-	termbox.Flush()
+	rend.SetCursor(letterCol(loc.X), loc.Y+1)
+	rend.Flush()
 }
 
-func handleKeyEvent(evt *termbox.Event) bool {
+func handleKeyEvent(evt *ui.Event) bool {
 	notice = ""
 	handled := true
 	switch evt.Key {
-	case termbox.KeyEsc:
+	case ui.KeyEsc:
 		mode = normalMode
-	case termbox.KeyTab:
+	case ui.KeyTab:
+		if mode == clueMode {
+			handled = false
+			break
+		}
 		toggleDir()
-	case termbox.KeySpace:
+	case ui.KeySpace:
+		if mode == clueMode {
+			handled = false
+			break
+		}
 		countDo(func() {
 			loc = cw.NextCell(loc, dir, true)
 		})
-	case termbox.KeyCtrlN:
+	case ui.KeyCtrlN:
+		if mode == clueMode {
+			handled = false
+			break
+		}
 		countDo(func() {
 			loc = cw.NextWord(loc, dir)
 		})
-	case termbox.KeyCtrlP:
+	case ui.KeyCtrlP:
+		if mode == clueMode {
+			handled = false
+			break
+		}
 		loc = cw.NextWord(loc, dir.Opposite())
 	default:
 		handled = false
@@ -177,6 +265,9 @@ func handleKeyEvent(evt *termbox.Event) bool {
 		switch evt.Ch {
 		case 'i':
 			mode = editMode
+		case 'R':
+			mode = rebusMode
+			rebusBuf = ""
 		case 'q':
 			save()
 			mode = quitMode
@@ -210,6 +301,9 @@ func handleKeyEvent(evt *termbox.Event) bool {
 			})
 		case 's':
 			save()
+		case '/':
+			mode = clueMode
+			clueBuf = ""
 		case 'C':
 			if count == 1053 {
 				cw.Solve()
@@ -220,11 +314,11 @@ func handleKeyEvent(evt *termbox.Event) bool {
 		}
 	case editMode:
 		switch evt.Key {
-		case termbox.KeyDelete:
+		case ui.KeyDelete:
 			set(crosswd.Empty)
 			loc = cw.NextCell(loc, dir, true)
 			return true
-		case termbox.KeyBackspace, termbox.KeyBackspace2:
+		case ui.KeyBackspace:
 			loc = cw.NextCell(loc, dir.Opposite(), true)
 			set(crosswd.Empty)
 			return true
@@ -234,6 +328,49 @@ func handleKeyEvent(evt *termbox.Event) bool {
 			set(byte(r))
 			loc = cw.NextCell(loc, dir, true)
 		}
+	case rebusMode:
+		switch evt.Key {
+		case ui.KeyEnter:
+			if rebusBuf != "" {
+				cw.SetRebus(loc, rebusBuf)
+				modified = true
+				loc = cw.NextCell(loc, dir, true)
+			}
+			mode = normalMode
+			return true
+		case ui.KeyBackspace:
+			if len(rebusBuf) > 0 {
+				rebusBuf = rebusBuf[:len(rebusBuf)-1]
+			}
+			return true
+		}
+		r := unicode.ToUpper(evt.Ch)
+		if r >= 'A' && r <= 'Z' {
+			rebusBuf += string(r)
+		}
+	case clueMode:
+		switch evt.Key {
+		case ui.KeyEnter:
+			if m := bestClueMatch(clueBuf); m != nil {
+				if pos, ok := cw.WordStart(m.id); ok {
+					loc = pos
+					dir = m.dir
+				}
+			}
+			mode = normalMode
+			return true
+		case ui.KeyBackspace:
+			if len(clueBuf) > 0 {
+				clueBuf = clueBuf[:len(clueBuf)-1]
+			}
+			return true
+		case ui.KeySpace:
+			clueBuf += " "
+			return true
+		}
+		if evt.Ch != 0 {
+			clueBuf += string(evt.Ch)
+		}
 	}
 	return resetCount
 }
@@ -272,8 +409,8 @@ func countDo(f func()) {
 func run() {
 	for {
 		draw()
-		switch evt := termbox.PollEvent(); evt.Type {
-		case termbox.EventKey:
+		switch evt := rend.PollEvent(); evt.Type {
+		case ui.EventKey:
 			if handleKeyEvent(&evt) {
 				count = 0
 			}
@@ -288,10 +425,12 @@ func main() {
 	log.SetFlags(0)
 	log.SetPrefix("crosswd: ")
 
-	if len(os.Args) < 2 {
-		log.Fatal("usage: crosswd crossword.puz")
+	key := flag.Uint("k", 0, "key to unlock a scrambled puzzle")
+	flag.Parse()
+	if flag.NArg() < 1 {
+		log.Fatal("usage: crosswd [-k key] crossword.puz")
 	}
-	filename = os.Args[1]
+	filename = flag.Arg(0)
 	in, err := os.Open(filename)
 	if err != nil {
 		log.Fatal(err)
@@ -306,13 +445,18 @@ func main() {
 			log.Fatal(err)
 		}
 	}
+	if err := cw.Unlock(uint16(*key)); err != nil {
+		log.Fatal(err)
+	}
 	cw.Setup()
+	setCellWidth()
 	loc = cw.NextCell(crosswd.Coord{-1, 0}, dir, true)
 
-	if err := termbox.Init(); err != nil {
+	rend = ui.New()
+	if err := rend.Init(); err != nil {
 		log.Fatal(err)
 	}
-	defer termbox.Close()
+	defer rend.Close()
 
 	run()
 }
@@ -350,6 +494,7 @@ func save() error {
 }
 
 func set(c byte) {
+	cw.ClearRebus(loc)
 	if cw.Set(loc, c) {
 		modified = true
 	}
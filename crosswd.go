@@ -155,23 +155,33 @@ type Puzzle struct {
 	Copyright string
 	Clues     []string
 	Notes     string
-	Extra     []byte
 
-	cellID  map[Coord]int
-	idCell  map[int]Coord
-	clueNum map[Direction]map[int]int
-	enc     *encoding.Encoder
-	dec     *encoding.Decoder
+	// CellFlags, Rebus, UserRebus, and Timer hold the GEXT, GRBS/RTBL,
+	// RUSR, and LTIM sections respectively. Each is nil (or empty) if the
+	// file carried no such section.
+	CellFlags *Grid
+	Rebus     *Rebus
+	UserRebus []string
+	Timer     *Timer
+
+	cellID       map[Coord]int
+	idCell       map[int]Coord
+	clueNum      map[Direction]map[int]int
+	enc          *encoding.Encoder
+	dec          *encoding.Decoder
+	extraOrder   []string
+	extraUnknown map[string][]byte
 }
 
 // New creates a Puzzle instance
 func New() *Puzzle {
 	return &Puzzle{
-		cellID:  map[Coord]int{},
-		idCell:  map[int]Coord{},
-		clueNum: map[Direction]map[int]int{Right: {}, Down: {}},
-		enc:     charmap.ISO8859_1.NewEncoder(),
-		dec:     charmap.ISO8859_1.NewDecoder(),
+		cellID:       map[Coord]int{},
+		idCell:       map[int]Coord{},
+		clueNum:      map[Direction]map[int]int{Right: {}, Down: {}},
+		enc:          charmap.ISO8859_1.NewEncoder(),
+		dec:          charmap.ISO8859_1.NewDecoder(),
+		extraUnknown: map[string][]byte{},
 	}
 }
 
@@ -208,7 +218,7 @@ func (p *Puzzle) Read(r io.Reader) error {
 	}
 	p.Notes, _ = p.dec.String(outFields[3+p.Header.NumClues])
 	if len(inFields) > len(outFields) {
-		p.Extra = []byte(inFields[len(inFields)-1])
+		p.parseExtra([]byte(inFields[len(inFields)-1]))
 	}
 	if p.Cksum() != p.Header.Cksum || p.MagicCksum() != p.Header.MagicCksum ||
 		p.HeaderCksum() != p.Header.HeaderCksum {
@@ -221,7 +231,9 @@ func (p *Puzzle) Read(r io.Reader) error {
 // Write writes crossword data in .puz format
 func (p *Puzzle) Write(w io.Writer) error {
 	p.Header.HeaderCksum = p.HeaderCksum()
-	p.Header.MagicCksum = p.MagicCksum()
+	if !p.Locked() {
+		p.Header.MagicCksum = p.MagicCksum()
+	}
 	p.Header.Cksum = p.Cksum()
 
 	if err := binary.Write(w, binary.LittleEndian, p.Header); err != nil {
@@ -250,7 +262,7 @@ func (p *Puzzle) Write(w io.Writer) error {
 	if _, err := w.Write(p.encode(p.Notes + "\x00")); err != nil {
 		return err
 	}
-	if _, err := w.Write(p.Extra); err != nil {
+	if err := p.writeExtra(w); err != nil {
 		return err
 	}
 	return nil
@@ -374,14 +386,50 @@ func (p *Puzzle) Setup() {
 	}
 }
 
-// Verify returns whether the working grid matches the solition.
+// Verify returns whether the working grid matches the solution. Cells with a
+// solution rebus compare their full rebus string; all other cells compare
+// just their single letter.
 func (p *Puzzle) Verify() bool {
-	return bytes.Equal(p.elts, p.Solution.elts)
+	if p.Rebus == nil && len(p.UserRebus) == 0 {
+		return bytes.Equal(p.elts, p.Solution.elts)
+	}
+	sz := p.Size()
+	for y := 0; y < sz.Y; y++ {
+		for x := 0; x < sz.X; x++ {
+			pos := Coord{x, y}
+			if sol, ok := p.solutionRebus(pos); ok {
+				entry, _ := p.RebusAt(pos)
+				if entry != sol {
+					return false
+				}
+				continue
+			}
+			c, _ := p.At(pos)
+			sc, _ := p.Solution.At(pos)
+			if c != sc {
+				return false
+			}
+		}
+	}
+	return true
 }
 
-// Solve fills the working grid with the solution
+// Solve fills the working grid with the solution, including any rebus
+// squares.
 func (p *Puzzle) Solve() {
 	copy(p.elts, p.Solution.elts)
+	if p.Rebus == nil {
+		return
+	}
+	sz := p.Size()
+	for y := 0; y < sz.Y; y++ {
+		for x := 0; x < sz.X; x++ {
+			pos := Coord{x, y}
+			if s, ok := p.solutionRebus(pos); ok {
+				p.SetRebus(pos, s)
+			}
+		}
+	}
 }
 
 // HeaderCksum calculates base checksum
@@ -0,0 +1,307 @@
+package crosswd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GEXT cell flag bits
+const (
+	Circled         byte = 0x80
+	PreviouslyWrong byte = 0x10
+	CurrentlyWrong  byte = 0x20
+	Revealed        byte = 0x40
+)
+
+// Rebus holds the solution rebus squares parsed from the GRBS and RTBL
+// sections. Grid holds a per-cell lookup index (0 means no rebus, N means
+// look up N-1 in Table); Table maps that index to the rebus answer string.
+type Rebus struct {
+	Grid  *Grid
+	Table map[int]string
+}
+
+// Timer holds the solving-clock state parsed from the LTIM section.
+type Timer struct {
+	Elapsed int
+	Running bool
+}
+
+// RebusAt returns the working grid's rebus answer at pos, if any, and
+// whether pos currently holds one.
+func (p *Puzzle) RebusAt(pos Coord) (string, bool) {
+	if !p.Valid(pos) || len(p.UserRebus) == 0 {
+		return "", false
+	}
+	s := p.UserRebus[p.cellIdx(pos)]
+	return s, s != ""
+}
+
+// SetRebus stores a multi-character rebus answer at pos in the working
+// grid and returns whether pos was valid. The cell's plain byte value is
+// set to the answer's first letter, so code that only looks at the grid
+// keeps working.
+func (p *Puzzle) SetRebus(pos Coord, s string) bool {
+	if !p.Valid(pos) {
+		return false
+	}
+	if len(p.UserRebus) == 0 {
+		sz := p.Size()
+		p.UserRebus = make([]string, sz.X*sz.Y)
+	}
+	p.UserRebus[p.cellIdx(pos)] = s
+	first := byte(Empty)
+	if len(s) > 0 {
+		first = s[0]
+	}
+	p.Set(pos, first)
+	return true
+}
+
+// ClearRebus removes pos's working-grid rebus answer, if any, without
+// touching the cell's plain byte value. Callers that overwrite or clear a
+// cell's letter directly should call this first so a stale rebus answer
+// doesn't keep overriding the display.
+func (p *Puzzle) ClearRebus(pos Coord) {
+	if !p.Valid(pos) || len(p.UserRebus) == 0 {
+		return
+	}
+	p.UserRebus[p.cellIdx(pos)] = ""
+}
+
+// solutionRebus returns the solution's rebus answer at pos, if any, and
+// whether pos is a solution rebus square.
+func (p *Puzzle) solutionRebus(pos Coord) (string, bool) {
+	if p.Rebus == nil {
+		return "", false
+	}
+	n, ok := p.Rebus.Grid.At(pos)
+	if !ok || n == 0 {
+		return "", false
+	}
+	s, ok := p.Rebus.Table[int(n)-1]
+	return s, ok
+}
+
+func (p *Puzzle) cellIdx(pos Coord) int {
+	return pos.Y*p.Size().X + pos.X
+}
+
+// parseExtra parses the trailing .puz sections (GEXT, GRBS, RTBL, RUSR,
+// LTIM) found after the notes field, populating the corresponding Puzzle
+// fields. Sections with unrecognized tags are kept as-is so Write can
+// reproduce them unchanged.
+func (p *Puzzle) parseExtra(data []byte) {
+	var rebusGrid *Grid
+	var rebusTable map[int]string
+	sz := p.Size()
+	for len(data) >= 8 {
+		tag := string(data[:4])
+		length := int(binary.LittleEndian.Uint16(data[4:6]))
+		end := 8 + length
+		if end > len(data) {
+			break
+		}
+		payload := data[8:end]
+		switch tag {
+		case "GEXT":
+			p.CellFlags = NewGrid(sz.X, sz.Y)
+			copy(p.CellFlags.elts, payload)
+		case "GRBS":
+			rebusGrid = NewGrid(sz.X, sz.Y)
+			copy(rebusGrid.elts, payload)
+		case "RTBL":
+			rebusTable = p.parseRebusTable(payload)
+		case "RUSR":
+			p.UserRebus = p.parseUserRebus(payload, sz)
+		case "LTIM":
+			p.Timer = parseTimer(payload)
+		default:
+			p.extraUnknown[tag] = append([]byte(nil), payload...)
+		}
+		p.extraOrder = append(p.extraOrder, tag)
+		data = data[end:]
+		if len(data) > 0 && data[0] == 0 {
+			data = data[1:]
+		}
+	}
+	if rebusGrid != nil || rebusTable != nil {
+		if rebusGrid == nil {
+			rebusGrid = NewGrid(sz.X, sz.Y)
+		}
+		if rebusTable == nil {
+			rebusTable = map[int]string{}
+		}
+		p.Rebus = &Rebus{Grid: rebusGrid, Table: rebusTable}
+	}
+}
+
+// writeExtra writes the trailing .puz sections, recomputing each section's
+// checksum, in the order they were originally read, followed by any new
+// sections implied by fields that were set since Read (or that were never
+// read at all, for a freshly built Puzzle).
+func (p *Puzzle) writeExtra(w io.Writer) error {
+	order := append([]string(nil), p.extraOrder...)
+	have := map[string]bool{}
+	for _, tag := range order {
+		have[tag] = true
+	}
+	ensure := func(tag string) {
+		if !have[tag] {
+			order = append(order, tag)
+			have[tag] = true
+		}
+	}
+	if p.CellFlags != nil {
+		ensure("GEXT")
+	}
+	if p.Rebus != nil {
+		ensure("GRBS")
+		ensure("RTBL")
+	}
+	if len(p.UserRebus) > 0 {
+		ensure("RUSR")
+	}
+	if p.Timer != nil {
+		ensure("LTIM")
+	}
+	for _, tag := range order {
+		var payload []byte
+		switch tag {
+		case "GEXT":
+			if p.CellFlags == nil {
+				continue
+			}
+			payload = p.CellFlags.elts
+		case "GRBS":
+			if p.Rebus == nil {
+				continue
+			}
+			payload = p.Rebus.Grid.elts
+		case "RTBL":
+			if p.Rebus == nil {
+				continue
+			}
+			payload = p.formatRebusTable(p.Rebus.Table)
+		case "RUSR":
+			if len(p.UserRebus) == 0 {
+				continue
+			}
+			payload = p.formatUserRebus(p.UserRebus)
+		case "LTIM":
+			if p.Timer == nil {
+				continue
+			}
+			payload = formatTimer(p.Timer)
+		default:
+			payload = p.extraUnknown[tag]
+		}
+		if err := writeExtraSection(w, tag, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeExtraSection(w io.Writer, tag string, payload []byte) error {
+	if _, err := w.Write([]byte(tag)); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(payload))); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, calcCksum(payload, 0)); err != nil {
+		return err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return err
+	}
+	_, err := w.Write([]byte{0})
+	return err
+}
+
+// parseRebusTable parses an RTBL payload, a ';'-terminated list of
+// "NN:string;" entries, into a lookup-index -> answer map.
+func (p *Puzzle) parseRebusTable(payload []byte) map[int]string {
+	table := map[int]string{}
+	for _, entry := range strings.Split(string(payload), ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil {
+			continue
+		}
+		val, _ := p.dec.String(parts[1])
+		table[key] = val
+	}
+	return table
+}
+
+func (p *Puzzle) formatRebusTable(table map[int]string) []byte {
+	keys := make([]int, 0, len(table))
+	for k := range table {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	buf := &bytes.Buffer{}
+	for _, k := range keys {
+		fmt.Fprintf(buf, "%2d:%s;", k, string(p.encode(table[k])))
+	}
+	return buf.Bytes()
+}
+
+// parseUserRebus parses a RUSR payload, NUL-terminated per-cell strings in
+// row-major order, into one entry per grid cell (empty if unset).
+func (p *Puzzle) parseUserRebus(payload []byte, sz Coord) []string {
+	n := sz.X * sz.Y
+	fields := bytes.Split(payload, []byte{0})
+	out := make([]string, n)
+	for i := 0; i < n && i < len(fields); i++ {
+		out[i], _ = p.dec.String(string(fields[i]))
+	}
+	return out
+}
+
+// formatUserRebus formats cells back into a RUSR payload, NUL-terminating
+// every cell (including the last) to match the format parseUserRebus reads.
+func (p *Puzzle) formatUserRebus(cells []string) []byte {
+	buf := &bytes.Buffer{}
+	for _, s := range cells {
+		buf.Write(p.encode(s))
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+// parseTimer parses an LTIM payload, an ASCII "elapsed,stopped" pair.
+func parseTimer(payload []byte) *Timer {
+	fields := strings.SplitN(string(payload), ",", 2)
+	if len(fields) != 2 {
+		return nil
+	}
+	elapsed, err1 := strconv.Atoi(fields[0])
+	stopped, err2 := strconv.Atoi(fields[1])
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+	return &Timer{Elapsed: elapsed, Running: stopped == 0}
+}
+
+func formatTimer(t *Timer) []byte {
+	stopped := 0
+	if !t.Running {
+		stopped = 1
+	}
+	return []byte(fmt.Sprintf("%d,%d", t.Elapsed, stopped))
+}
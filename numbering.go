@@ -0,0 +1,54 @@
+package crosswd
+
+import "sort"
+
+// Number returns the clue number for pos, or 0 if pos doesn't start a word.
+func (p *Puzzle) Number(pos Coord) int {
+	return p.cellID[pos]
+}
+
+// Numbers returns a grid the same size as the puzzle, holding each
+// numbered cell's clue number and 0 elsewhere, analogous to hpuz's
+// numberGrid.
+func (p *Puzzle) Numbers() *Grid {
+	sz := p.Size()
+	g := NewGrid(sz.X, sz.Y)
+	for pos, id := range p.cellID {
+		g.Set(pos, byte(id))
+	}
+	return g
+}
+
+// WordCells returns the cells, in order, of the word numbered id in
+// direction dir, or nil if id doesn't start a word in that direction.
+func (p *Puzzle) WordCells(id int, dir Direction) []Coord {
+	start, ok := p.WordStart(id)
+	if !ok {
+		return nil
+	}
+	if _, ok := p.clueNum[dir][id]; !ok {
+		return nil
+	}
+	end := p.WordExtent(start, dir)
+	var cells []Coord
+	for pos := start; ; pos = p.NextCell(pos, dir, false) {
+		cells = append(cells, pos)
+		if pos == end {
+			break
+		}
+	}
+	return cells
+}
+
+// Words returns the clue numbers of all words in direction dir, in grid
+// reading order, for building a clue list.
+func (p *Puzzle) Words(dir Direction) []int {
+	ids := make([]int, 0, len(p.clueNum[dir]))
+	for id := range p.clueNum[dir] {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return p.clueNum[dir][ids[i]] < p.clueNum[dir][ids[j]]
+	})
+	return ids
+}
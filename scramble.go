@@ -0,0 +1,157 @@
+package crosswd
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ScrambledBit is the BitMask2 flag indicating a scrambled (locked) solution.
+const ScrambledBit uint16 = 0x0004
+
+// Locked returns whether the puzzle's solution is currently scrambled.
+func (p *Puzzle) Locked() bool {
+	return p.bitMask2()&ScrambledBit != 0
+}
+
+// Unlock unscrambles a locked puzzle's solution using key, clearing the
+// scrambled bit on success. It is a no-op if the puzzle isn't locked.
+func (p *Puzzle) Unlock(key uint16) error {
+	if !p.Locked() {
+		return nil
+	}
+	letters, coords := p.collectSolutionLetters()
+	s := letters
+	for _, d := range keyDigits(key) {
+		s = shiftString(s, -d)
+		s = unsplitScramble(s)
+	}
+	if calcCksum([]byte(s), 0) != p.scrambledCksum() {
+		return errors.New("incorrect key")
+	}
+	p.scatterSolutionLetters(s, coords)
+	p.setBitMask2(p.bitMask2() &^ ScrambledBit)
+	return nil
+}
+
+// Lock scrambles the puzzle's solution using key, the inverse of Unlock,
+// and sets the scrambled bit. It is an error to lock an already-locked
+// puzzle.
+func (p *Puzzle) Lock(key uint16) error {
+	if p.Locked() {
+		return errors.New("puzzle is already locked")
+	}
+	letters, coords := p.collectSolutionLetters()
+	p.setBitMask2(p.bitMask2() | ScrambledBit)
+	// Refresh all four sub-checksums before patching in the scrambled
+	// solution slot below, so Write (which leaves MagicCksum alone while
+	// locked) doesn't persist a stale header/grid/text checksum.
+	p.Header.MagicCksum = p.MagicCksum()
+	p.setScrambledCksum(calcCksum([]byte(letters), 0))
+	s := letters
+	digits := keyDigits(key)
+	for i := len(digits) - 1; i >= 0; i-- {
+		s = splitScramble(s)
+		s = shiftString(s, digits[i])
+	}
+	p.scatterSolutionLetters(s, coords)
+	return nil
+}
+
+// collectSolutionLetters returns the non-black solution letters in
+// column-major order, along with the coordinate each letter came from.
+func (p *Puzzle) collectSolutionLetters() (string, []Coord) {
+	sz := p.Size()
+	var letters []byte
+	var coords []Coord
+	for x := 0; x < sz.X; x++ {
+		for y := 0; y < sz.Y; y++ {
+			pos := Coord{x, y}
+			c, _ := p.Solution.At(pos)
+			if c == Blank {
+				continue
+			}
+			letters = append(letters, c)
+			coords = append(coords, pos)
+		}
+	}
+	return string(letters), coords
+}
+
+// scatterSolutionLetters writes s back into the solution grid at coords,
+// the inverse of collectSolutionLetters.
+func (p *Puzzle) scatterSolutionLetters(s string, coords []Coord) {
+	for i, pos := range coords {
+		p.Solution.Set(pos, s[i])
+	}
+}
+
+// keyDigits splits a 4-digit scramble key into its decimal digits, most
+// significant first.
+func keyDigits(key uint16) [4]int {
+	return [4]int{
+		int(key / 1000 % 10),
+		int(key / 100 % 10),
+		int(key / 10 % 10),
+		int(key % 10),
+	}
+}
+
+// shiftString shifts each letter in s by n (mod 26), wrapping within A-Z.
+// n may be negative to shift backward.
+func shiftString(s string, n int) string {
+	b := []byte(s)
+	for i, c := range b {
+		b[i] = byte(((int(c-'A')+n)%26+26)%26) + 'A'
+	}
+	return string(b)
+}
+
+// splitScramble rearranges s into its odd-indexed characters followed by
+// its even-indexed characters, one step of the scramble transform.
+func splitScramble(s string) string {
+	var odds, evens []byte
+	for i := 0; i < len(s); i++ {
+		if i%2 == 1 {
+			odds = append(odds, s[i])
+		} else {
+			evens = append(evens, s[i])
+		}
+	}
+	return string(odds) + string(evens)
+}
+
+// unsplitScramble is the inverse of splitScramble: it interleaves a string
+// of odds followed by evens back into its original order.
+func unsplitScramble(s string) string {
+	half := len(s) / 2
+	odds, evens := s[:half], s[half:]
+	out := make([]byte, len(s))
+	for i := 0; i < len(evens); i++ {
+		out[2*i] = evens[i]
+	}
+	for i := 0; i < len(odds); i++ {
+		out[2*i+1] = odds[i]
+	}
+	return string(out)
+}
+
+// scrambledCksum returns the checksum that validates an unlock key,
+// stored in the solution slot of Header.MagicCksum.
+func (p *Puzzle) scrambledCksum() uint16 {
+	lo := p.Header.MagicCksum[1] ^ CksumMagic[1]
+	hi := p.Header.MagicCksum[5] ^ CksumMagic[5]
+	return uint16(lo) | uint16(hi)<<8
+}
+
+func (p *Puzzle) setScrambledCksum(cksum uint16) {
+	p.Header.MagicCksum[1] = byte(cksum) ^ CksumMagic[1]
+	p.Header.MagicCksum[5] = byte(cksum>>8) ^ CksumMagic[5]
+}
+
+func (p *Puzzle) bitMask2() uint16 {
+	return binary.LittleEndian.Uint16(p.Header.BitMask2[:])
+}
+
+func (p *Puzzle) setBitMask2(v uint16) {
+	binary.LittleEndian.PutUint16(p.Header.BitMask2[:], v)
+}
@@ -0,0 +1,92 @@
+//go:build tcell
+// +build tcell
+
+package ui
+
+import "github.com/gdamore/tcell/v2"
+
+// New returns a tcell-backed Renderer, selected with `go build -tags tcell`.
+func New() Renderer { return &tcellRenderer{} }
+
+type tcellRenderer struct {
+	screen tcell.Screen
+}
+
+func (r *tcellRenderer) Init() error {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+	if err := screen.Init(); err != nil {
+		return err
+	}
+	r.screen = screen
+	return nil
+}
+
+func (r *tcellRenderer) Close() { r.screen.Fini() }
+
+func (r *tcellRenderer) Size() (int, int) { return r.screen.Size() }
+
+func (r *tcellRenderer) SetCell(x, y int, ch rune, fg, bg Attr) {
+	r.screen.SetContent(x, y, ch, nil, toTcellStyle(fg, bg))
+}
+
+func (r *tcellRenderer) SetCursor(x, y int) { r.screen.ShowCursor(x, y) }
+func (r *tcellRenderer) Flush()             { r.screen.Show() }
+
+func (r *tcellRenderer) PollEvent() Event {
+	for {
+		switch evt := r.screen.PollEvent().(type) {
+		case *tcell.EventKey:
+			return Event{Type: EventKey, Key: fromTcellKey(evt), Ch: evt.Rune()}
+		case *tcell.EventResize:
+			r.screen.Sync()
+			return Event{Type: EventResize}
+		}
+	}
+}
+
+var tcellColors = map[Attr]tcell.Color{
+	ColorDefault: tcell.ColorDefault,
+	ColorBlack:   tcell.ColorBlack,
+	ColorRed:     tcell.ColorRed,
+	ColorGreen:   tcell.ColorGreen,
+	ColorYellow:  tcell.ColorYellow,
+	ColorBlue:    tcell.ColorBlue,
+	ColorMagenta: tcell.ColorDarkMagenta,
+	ColorCyan:    tcell.ColorDarkCyan,
+	ColorWhite:   tcell.ColorWhite,
+}
+
+func toTcellStyle(fg, bg Attr) tcell.Style {
+	return tcell.StyleDefault.
+		Foreground(tcellColors[fg&0xff]).
+		Background(tcellColors[bg&0xff]).
+		Bold(fg&AttrBold != 0 || bg&AttrBold != 0).
+		Underline(fg&AttrUnderline != 0 || bg&AttrUnderline != 0)
+}
+
+func fromTcellKey(evt *tcell.EventKey) Key {
+	switch evt.Key() {
+	case tcell.KeyEsc:
+		return KeyEsc
+	case tcell.KeyEnter:
+		return KeyEnter
+	case tcell.KeyTab:
+		return KeyTab
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		return KeyBackspace
+	case tcell.KeyDelete:
+		return KeyDelete
+	case tcell.KeyCtrlN:
+		return KeyCtrlN
+	case tcell.KeyCtrlP:
+		return KeyCtrlP
+	case tcell.KeyRune:
+		if evt.Rune() == ' ' {
+			return KeySpace
+		}
+	}
+	return KeyNone
+}
@@ -0,0 +1,80 @@
+//go:build !tcell
+// +build !tcell
+
+package ui
+
+import "github.com/nsf/termbox-go"
+
+// New returns the default, termbox-backed Renderer.
+func New() Renderer { return &termboxRenderer{} }
+
+type termboxRenderer struct{}
+
+func (r *termboxRenderer) Init() error { return termbox.Init() }
+func (r *termboxRenderer) Close()      { termbox.Close() }
+
+func (r *termboxRenderer) Size() (int, int) { return termbox.Size() }
+
+func (r *termboxRenderer) SetCell(x, y int, ch rune, fg, bg Attr) {
+	termbox.SetCell(x, y, ch, toTermboxAttr(fg), toTermboxAttr(bg))
+}
+
+func (r *termboxRenderer) SetCursor(x, y int) { termbox.SetCursor(x, y) }
+func (r *termboxRenderer) Flush()             { termbox.Flush() }
+
+func (r *termboxRenderer) PollEvent() Event {
+	for {
+		evt := termbox.PollEvent()
+		switch evt.Type {
+		case termbox.EventKey:
+			return Event{Type: EventKey, Key: fromTermboxKey(evt.Key), Ch: evt.Ch}
+		case termbox.EventResize:
+			return Event{Type: EventResize}
+		}
+	}
+}
+
+var termboxColors = map[Attr]termbox.Attribute{
+	ColorDefault: termbox.ColorDefault,
+	ColorBlack:   termbox.ColorBlack,
+	ColorRed:     termbox.ColorRed,
+	ColorGreen:   termbox.ColorGreen,
+	ColorYellow:  termbox.ColorYellow,
+	ColorBlue:    termbox.ColorBlue,
+	ColorMagenta: termbox.ColorMagenta,
+	ColorCyan:    termbox.ColorCyan,
+	ColorWhite:   termbox.ColorWhite,
+}
+
+func toTermboxAttr(a Attr) termbox.Attribute {
+	out := termboxColors[a&0xff]
+	if a&AttrBold != 0 {
+		out |= termbox.AttrBold
+	}
+	if a&AttrUnderline != 0 {
+		out |= termbox.AttrUnderline
+	}
+	return out
+}
+
+func fromTermboxKey(k termbox.Key) Key {
+	switch k {
+	case termbox.KeyEsc:
+		return KeyEsc
+	case termbox.KeyEnter:
+		return KeyEnter
+	case termbox.KeyTab:
+		return KeyTab
+	case termbox.KeySpace:
+		return KeySpace
+	case termbox.KeyBackspace, termbox.KeyBackspace2:
+		return KeyBackspace
+	case termbox.KeyDelete:
+		return KeyDelete
+	case termbox.KeyCtrlN:
+		return KeyCtrlN
+	case termbox.KeyCtrlP:
+		return KeyCtrlP
+	}
+	return KeyNone
+}
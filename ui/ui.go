@@ -0,0 +1,73 @@
+// Package ui abstracts the crosswd terminal front-end over different
+// terminal libraries, so the crosswd package itself stays free of any
+// particular terminal dependency.
+package ui
+
+// Key identifies a non-printable key press, or the space bar (KeySpace).
+// Other printable characters arrive via Event.Ch instead, with
+// Key == KeyNone.
+type Key int
+
+// Recognized keys.
+const (
+	KeyNone Key = iota
+	KeyEsc
+	KeyEnter
+	KeyTab
+	KeySpace
+	KeyBackspace
+	KeyDelete
+	KeyCtrlN
+	KeyCtrlP
+)
+
+// EventType distinguishes the events PollEvent can return.
+type EventType int
+
+// Event types.
+const (
+	EventKey EventType = iota
+	EventResize
+)
+
+// Event represents a single input event.
+type Event struct {
+	Type EventType
+	Key  Key
+	Ch   rune
+}
+
+// Attr represents a cell's foreground or background color, optionally
+// combined with style flags via bitwise OR, e.g. ColorWhite|AttrUnderline.
+type Attr uint16
+
+// Colors.
+const (
+	ColorDefault Attr = iota
+	ColorBlack
+	ColorRed
+	ColorGreen
+	ColorYellow
+	ColorBlue
+	ColorMagenta
+	ColorCyan
+	ColorWhite
+)
+
+// Style flags, OR'd onto a color.
+const (
+	AttrBold Attr = 1 << (8 + iota)
+	AttrUnderline
+)
+
+// Renderer draws to and reads input from a terminal. Implementations are
+// selected at build time; see renderer_termbox.go and renderer_tcell.go.
+type Renderer interface {
+	Init() error
+	Close()
+	Size() (int, int)
+	SetCell(x, y int, r rune, fg, bg Attr)
+	SetCursor(x, y int)
+	Flush()
+	PollEvent() Event
+}